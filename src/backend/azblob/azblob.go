@@ -0,0 +1,95 @@
+// Package azblob implements the backend.Backend interface on top of
+// Azure Blob Storage.
+package azblob
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+
+	"github.com/yyobject/file-share/src/backend"
+)
+
+func init() {
+	backend.Register("azblob", New)
+}
+
+// Options holds the Azure Blob Storage connection settings, populated
+// from the AZBLOB_* keys in the layered config sources.
+type Options struct {
+	AccountName   string
+	AccountKey    string
+	ContainerName string
+	Domain        string // optional custom public URL (e.g. a CDN endpoint)
+}
+
+// Backend uploads to an Azure Blob Storage container.
+type Backend struct {
+	opts   Options
+	client *azblob.Client
+}
+
+// New builds an Azure backend.Backend from opts. Required keys:
+// account_name, account_key, container_name. domain is optional.
+func New(opts map[string]string) (backend.Backend, error) {
+	o := Options{
+		AccountName:   opts["account_name"],
+		AccountKey:    opts["account_key"],
+		ContainerName: opts["container_name"],
+		Domain:        opts["domain"],
+	}
+
+	var missing []string
+	if o.AccountName == "" {
+		missing = append(missing, "account_name")
+	}
+	if o.AccountKey == "" {
+		missing = append(missing, "account_key")
+	}
+	if o.ContainerName == "" {
+		missing = append(missing, "container_name")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("azblob: missing config: %s", strings.Join(missing, ", "))
+	}
+
+	cred, err := azblob.NewSharedKeyCredential(o.AccountName, o.AccountKey)
+	if err != nil {
+		return nil, fmt.Errorf("azblob: invalid credentials: %v", err)
+	}
+
+	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", o.AccountName)
+	client, err := azblob.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("azblob: failed to create client: %v", err)
+	}
+
+	return &Backend{opts: o, client: client}, nil
+}
+
+// PutFile uploads localPath to key.
+func (b *Backend) PutFile(key, localPath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = b.client.UploadFile(context.Background(), b.opts.ContainerName, key, f, nil)
+	return err
+}
+
+// PublicURL returns the URL a client can use to fetch key.
+func (b *Backend) PublicURL(key string) string {
+	if b.opts.Domain != "" {
+		domain := strings.TrimRight(b.opts.Domain, "/")
+		if !strings.HasPrefix(domain, "http://") && !strings.HasPrefix(domain, "https://") {
+			domain = "https://" + domain
+		}
+		return fmt.Sprintf("%s/%s", domain, key)
+	}
+	return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", b.opts.AccountName, b.opts.ContainerName, key)
+}
@@ -0,0 +1,100 @@
+// Package b2 implements the backend.Backend interface on top of
+// Backblaze B2.
+package b2
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kurin/blazer/b2"
+
+	"github.com/yyobject/file-share/src/backend"
+)
+
+func init() {
+	backend.Register("b2", New)
+}
+
+// Options holds the Backblaze B2 connection settings, populated from the
+// B2_* keys in the layered config sources.
+type Options struct {
+	AccountID  string
+	AppKey     string
+	BucketName string
+	Domain     string // optional custom public URL (e.g. a friendly URL or CDN)
+}
+
+// Backend uploads to a Backblaze B2 bucket.
+type Backend struct {
+	opts   Options
+	bucket *b2.Bucket
+}
+
+// New builds a B2 backend.Backend from opts. Required keys: account_id,
+// app_key, bucket_name. domain is optional.
+func New(opts map[string]string) (backend.Backend, error) {
+	o := Options{
+		AccountID:  opts["account_id"],
+		AppKey:     opts["app_key"],
+		BucketName: opts["bucket_name"],
+		Domain:     opts["domain"],
+	}
+
+	var missing []string
+	if o.AccountID == "" {
+		missing = append(missing, "account_id")
+	}
+	if o.AppKey == "" {
+		missing = append(missing, "app_key")
+	}
+	if o.BucketName == "" {
+		missing = append(missing, "bucket_name")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("b2: missing config: %s", strings.Join(missing, ", "))
+	}
+
+	ctx := context.Background()
+	client, err := b2.NewClient(ctx, o.AccountID, o.AppKey)
+	if err != nil {
+		return nil, fmt.Errorf("b2: failed to authenticate: %v", err)
+	}
+
+	bucket, err := client.Bucket(ctx, o.BucketName)
+	if err != nil {
+		return nil, fmt.Errorf("b2: failed to get bucket: %v", err)
+	}
+
+	return &Backend{opts: o, bucket: bucket}, nil
+}
+
+// PutFile uploads localPath to key.
+func (b *Backend) PutFile(key, localPath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	ctx := context.Background()
+	w := b.bucket.Object(key).NewWriter(ctx)
+	if _, err := w.ReadFrom(f); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// PublicURL returns the URL a client can use to fetch key.
+func (b *Backend) PublicURL(key string) string {
+	if b.opts.Domain != "" {
+		domain := strings.TrimRight(b.opts.Domain, "/")
+		if !strings.HasPrefix(domain, "http://") && !strings.HasPrefix(domain, "https://") {
+			domain = "https://" + domain
+		}
+		return fmt.Sprintf("%s/%s", domain, key)
+	}
+	return b.bucket.BaseURL() + "/file/" + b.opts.BucketName + "/" + key
+}
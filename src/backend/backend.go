@@ -0,0 +1,145 @@
+// Package backend defines the storage abstraction file-share uploads
+// through, plus a scheme registry so main.go never needs to know about
+// any concrete provider. Each provider package (oss, s3, azblob, b2,
+// webdav, file) registers itself from an init() func, the same pattern
+// rclone uses for its backends.
+package backend
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Backend is implemented by every storage provider file-share can upload to.
+type Backend interface {
+	// PutFile uploads the local file at localPath under key.
+	PutFile(key, localPath string) error
+	// PublicURL returns the URL a client can use to fetch key after upload.
+	PublicURL(key string) string
+}
+
+// MultipartConfig tunes a resumable, chunked upload.
+type MultipartConfig struct {
+	// Parallel is the number of parts uploaded concurrently.
+	Parallel int
+	// Resume causes PutFileMultipart to pick up a previous attempt's
+	// sidecar state file instead of starting the upload over.
+	Resume bool
+}
+
+// MultipartBackend is implemented by backends that support resumable,
+// concurrent multi-part uploads of large files. main.go uses it instead
+// of PutFile once a file crosses --multipart-threshold; backends without
+// native multipart support simply don't implement it.
+type MultipartBackend interface {
+	Backend
+	PutFileMultipart(key, localPath string, cfg MultipartConfig) error
+}
+
+// GCBackend is implemented by backends that can garbage-collect orphaned
+// multipart uploads left behind by interrupted runs.
+type GCBackend interface {
+	Backend
+	// GCUploads aborts multipart uploads initiated more than olderThan
+	// ago and reports how many were aborted.
+	GCUploads(olderThan time.Duration) (aborted int, err error)
+}
+
+// StatBackend is implemented by backends that can check whether an
+// object already exists without downloading it. --dedup uses this to
+// skip uploads whose content-addressed key is already present.
+type StatBackend interface {
+	Backend
+	// StatObject reports whether key exists and, if so, its size.
+	StatObject(key string) (exists bool, size int64, err error)
+}
+
+// StreamBackend is implemented by backends that can upload directly from
+// a reader without requiring the data to exist as a local file first.
+// --stream uses this to pipe a downloaded URL straight into the upload.
+type StreamBackend interface {
+	Backend
+	PutStream(key string, r io.Reader) error
+}
+
+// ListedObject describes one remote object as seen by ListBackend.
+type ListedObject struct {
+	Key          string
+	Size         int64
+	ETag         string
+	LastModified time.Time
+}
+
+// ListBackend is implemented by backends that can enumerate the objects
+// under a prefix and remove one by key. The sync/mirror subcommand uses
+// this to diff a local directory against its remote mirror.
+type ListBackend interface {
+	Backend
+	// List returns every object whose key has prefix, paginating
+	// internally so the caller never sees a backend's page-size limit.
+	List(prefix string) ([]ListedObject, error)
+	// DeleteObject removes key, used by "sync --delete".
+	DeleteObject(key string) error
+}
+
+// MetadataBackend is implemented by backends that can attach arbitrary
+// string metadata to an object. --encrypt uses this to store the salt,
+// algorithm and KDF params needed to decrypt without out-of-band info
+// (the nonce travels at the start of the ciphertext itself).
+type MetadataBackend interface {
+	Backend
+	// PutFileWithMeta uploads localPath under key carrying meta, in
+	// addition to whatever PutFile does.
+	PutFileWithMeta(key, localPath string, meta map[string]string) error
+	// GetMeta returns the metadata previously attached with
+	// PutFileWithMeta.
+	GetMeta(key string) (map[string]string, error)
+}
+
+// Factory builds a Backend from the flat set of options gathered from the
+// layered config sources (env files, env vars, --config). Each backend
+// defines its own option keys and validates them in its factory func.
+type Factory func(opts map[string]string) (Backend, error)
+
+var registry = map[string]Factory{}
+
+// Register makes a backend factory available under scheme (e.g. "oss",
+// "s3"). It is meant to be called from the init() of a backend package.
+func Register(scheme string, factory Factory) {
+	registry[scheme] = factory
+}
+
+// New builds a Backend for scheme from opts. It returns an error listing
+// the known schemes if scheme has no registered factory.
+func New(scheme string, opts map[string]string) (Backend, error) {
+	factory, ok := registry[scheme]
+	if !ok {
+		return nil, fmt.Errorf("unknown storage provider %q (available: %s)", scheme, joinSchemes())
+	}
+	return factory(opts)
+}
+
+// Schemes returns the registered provider schemes, sorted for stable
+// output in --check and in New's unknown-provider error.
+func Schemes() []string {
+	schemes := make([]string, 0, len(registry))
+	for scheme := range registry {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+func joinSchemes() string {
+	schemes := Schemes()
+	out := ""
+	for i, s := range schemes {
+		if i > 0 {
+			out += ", "
+		}
+		out += s
+	}
+	return out
+}
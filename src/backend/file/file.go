@@ -0,0 +1,85 @@
+// Package file implements the backend.Backend interface on top of a
+// local (or mounted network) directory. It exists mainly for testing the
+// rest of the pipeline without talking to a real object store, and for
+// users who serve a directory themselves (e.g. behind nginx).
+package file
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yyobject/file-share/src/backend"
+)
+
+func init() {
+	backend.Register("file", New)
+}
+
+// Options holds the local backend settings, populated from the FILE_*
+// keys in the layered config sources.
+type Options struct {
+	BaseDir string
+	Domain  string // optional public URL prefix files are served under
+}
+
+// Backend copies files into a local directory.
+type Backend struct {
+	opts Options
+}
+
+// New builds a local backend.Backend from opts. Required key: base_dir.
+// domain is optional; if unset, PublicURL returns a file:// URL.
+func New(opts map[string]string) (backend.Backend, error) {
+	o := Options{
+		BaseDir: opts["base_dir"],
+		Domain:  opts["domain"],
+	}
+
+	if o.BaseDir == "" {
+		return nil, fmt.Errorf("file: missing config: base_dir")
+	}
+
+	if err := os.MkdirAll(o.BaseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("file: failed to create base_dir: %v", err)
+	}
+
+	return &Backend{opts: o}, nil
+}
+
+// PutFile copies localPath into base_dir under key.
+func (b *Backend) PutFile(key, localPath string) error {
+	dest := filepath.Join(b.opts.BaseDir, key)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	src, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// PublicURL returns the URL a client can use to fetch key.
+func (b *Backend) PublicURL(key string) string {
+	if b.opts.Domain != "" {
+		domain := strings.TrimRight(b.opts.Domain, "/")
+		if !strings.HasPrefix(domain, "http://") && !strings.HasPrefix(domain, "https://") {
+			domain = "https://" + domain
+		}
+		return fmt.Sprintf("%s/%s", domain, key)
+	}
+	return "file://" + filepath.Join(b.opts.BaseDir, key)
+}
@@ -0,0 +1,50 @@
+package oss
+
+import (
+	"strings"
+
+	aliyunoss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+
+	"github.com/yyobject/file-share/src/backend"
+)
+
+// List returns every object under prefix, paginating through
+// ListObjectsV2 until the result is no longer truncated. Used by the
+// sync/mirror subcommand to build its remote-side view of the bucket.
+func (b *Backend) List(prefix string) ([]backend.ListedObject, error) {
+	var objects []backend.ListedObject
+	token := ""
+
+	for {
+		opts := []aliyunoss.Option{aliyunoss.Prefix(prefix)}
+		if token != "" {
+			opts = append(opts, aliyunoss.ContinuationToken(token))
+		}
+
+		result, err := b.bucket.ListObjectsV2(opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range result.Objects {
+			objects = append(objects, backend.ListedObject{
+				Key:          obj.Key,
+				Size:         obj.Size,
+				ETag:         strings.Trim(obj.ETag, `"`),
+				LastModified: obj.LastModified,
+			})
+		}
+
+		if !result.IsTruncated {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+
+	return objects, nil
+}
+
+// DeleteObject removes key, used by "sync --delete".
+func (b *Backend) DeleteObject(key string) error {
+	return b.bucket.DeleteObject(key)
+}
@@ -0,0 +1,37 @@
+package oss
+
+import (
+	"strings"
+
+	aliyunoss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// PutFileWithMeta uploads localPath to key, attaching meta as x-oss-meta-*
+// object headers. --encrypt uses this to carry the salt and KDF params a
+// later --decrypt needs, without any out-of-band state.
+func (b *Backend) PutFileWithMeta(key, localPath string, meta map[string]string) error {
+	opts := make([]aliyunoss.Option, 0, len(meta))
+	for k, v := range meta {
+		opts = append(opts, aliyunoss.Meta(k, v))
+	}
+	return b.bucket.PutObjectFromFile(key, localPath, opts...)
+}
+
+// GetMeta returns the x-oss-meta-* headers previously attached to key via
+// PutFileWithMeta, keyed by their lowercased suffix (e.g. "salt" for
+// X-Oss-Meta-Salt).
+func (b *Backend) GetMeta(key string) (map[string]string, error) {
+	header, err := b.bucket.GetObjectDetailedMeta(key)
+	if err != nil {
+		return nil, err
+	}
+
+	const prefix = "X-Oss-Meta-"
+	meta := map[string]string{}
+	for k := range header {
+		if strings.HasPrefix(k, prefix) {
+			meta[strings.ToLower(strings.TrimPrefix(k, prefix))] = header.Get(k)
+		}
+	}
+	return meta, nil
+}
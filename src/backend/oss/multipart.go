@@ -0,0 +1,215 @@
+package oss
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	aliyunoss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+
+	"github.com/yyobject/file-share/src/backend"
+)
+
+// defaultPartSize is the size of each part in a multipart upload. OSS
+// requires parts (other than the last) to be at least 100 KiB; 8 MiB
+// keeps the part count reasonable for multi-GB files without wasting
+// memory per in-flight part.
+const defaultPartSize int64 = 8 * 1024 * 1024
+
+// PutFileMultipart uploads localPath to key using OSS's multipart upload
+// API, with cfg.Parallel parts in flight at once. If cfg.Resume is set
+// and a sidecar state file exists for key, already-uploaded parts are
+// skipped instead of re-sent.
+func (b *Backend) PutFileMultipart(key, localPath string, cfg backend.MultipartConfig) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+
+	parallel := cfg.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	path := statePath(key)
+	var state *uploadState
+	if cfg.Resume {
+		state = loadState(path)
+	}
+
+	var imur aliyunoss.InitiateMultipartUploadResult
+	if state != nil && state.UploadID != "" {
+		imur = aliyunoss.InitiateMultipartUploadResult{Bucket: b.opts.BucketName, Key: key, UploadID: state.UploadID}
+	} else {
+		imur, err = b.bucket.InitiateMultipartUpload(key)
+		if err != nil {
+			return fmt.Errorf("oss: failed to initiate multipart upload: %v", err)
+		}
+		state = &uploadState{
+			Key:      key,
+			UploadID: imur.UploadID,
+			PartSize: defaultPartSize,
+			Created:  time.Now().UTC().Format(time.RFC3339),
+		}
+		if err := saveState(path, state); err != nil {
+			return fmt.Errorf("oss: failed to write state file: %v", err)
+		}
+	}
+
+	done := map[int]bool{}
+	for _, p := range state.Parts {
+		done[p.PartNumber] = true
+	}
+
+	numParts := int((info.Size() + state.PartSize - 1) / state.PartSize)
+	if numParts == 0 {
+		numParts = 1
+	}
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		sem      = make(chan struct{}, parallel)
+		pc       = newPacer()
+	)
+
+	for partNumber := 1; partNumber <= numParts; partNumber++ {
+		if done[partNumber] {
+			continue
+		}
+
+		offset := int64(partNumber-1) * state.PartSize
+		size := state.PartSize
+		if offset+size > info.Size() {
+			size = info.Size() - offset
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(partNumber int, offset, size int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			buf := make([]byte, size)
+			if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
+				recordErr(&mu, &firstErr, err)
+				return
+			}
+
+			sum := md5.Sum(buf)
+			localMD5 := hex.EncodeToString(sum[:])
+
+			var part aliyunoss.UploadPart
+			err := pc.call(func() error {
+				var uerr error
+				part, uerr = b.bucket.UploadPart(imur, bytes.NewReader(buf), size, partNumber)
+				return uerr
+			})
+			if err != nil {
+				recordErr(&mu, &firstErr, fmt.Errorf("part %d: %v", partNumber, err))
+				return
+			}
+
+			if etagMD5(part.ETag) != localMD5 {
+				recordErr(&mu, &firstErr, fmt.Errorf("part %d: MD5 mismatch after upload (local %s, etag %s)", partNumber, localMD5, part.ETag))
+				return
+			}
+
+			mu.Lock()
+			state.Parts = append(state.Parts, partState{PartNumber: partNumber, ETag: part.ETag, MD5: localMD5})
+			saveState(path, state)
+			mu.Unlock()
+		}(partNumber, offset, size)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return fmt.Errorf("oss: multipart upload failed (resume with --resume): %v", firstErr)
+	}
+
+	sort.Slice(state.Parts, func(i, j int) bool { return state.Parts[i].PartNumber < state.Parts[j].PartNumber })
+
+	ossParts := make([]aliyunoss.UploadPart, len(state.Parts))
+	for i, p := range state.Parts {
+		ossParts[i] = aliyunoss.UploadPart{PartNumber: p.PartNumber, ETag: p.ETag}
+	}
+
+	if _, err := b.bucket.CompleteMultipartUpload(imur, ossParts); err != nil {
+		return fmt.Errorf("oss: failed to complete multipart upload: %v", err)
+	}
+
+	os.Remove(path)
+	return nil
+}
+
+// GCUploads aborts multipart uploads initiated more than olderThan ago,
+// cleaning up storage left behind by runs that were killed mid-upload.
+// It pages through ListMultipartUploads the same way List pages through
+// ListObjectsV2, since a bucket can have more outstanding uploads than
+// fit in one response.
+func (b *Backend) GCUploads(olderThan time.Duration) (int, error) {
+	cutoff := time.Now().Add(-olderThan)
+	aborted := 0
+
+	keyMarker, uploadIDMarker := "", ""
+	for {
+		opts := []aliyunoss.Option{}
+		if keyMarker != "" {
+			opts = append(opts, aliyunoss.KeyMarker(keyMarker), aliyunoss.UploadIDMarker(uploadIDMarker))
+		}
+
+		result, err := b.bucket.ListMultipartUploads(opts...)
+		if err != nil {
+			return aborted, fmt.Errorf("oss: failed to list multipart uploads: %v", err)
+		}
+
+		for _, upload := range result.Uploads {
+			if upload.Initiated.IsZero() || upload.Initiated.After(cutoff) {
+				continue
+			}
+
+			imur := aliyunoss.InitiateMultipartUploadResult{
+				Bucket:   b.opts.BucketName,
+				Key:      upload.Key,
+				UploadID: upload.UploadID,
+			}
+			if err := b.bucket.AbortMultipartUpload(imur); err == nil {
+				aborted++
+			}
+		}
+
+		if !result.IsTruncated {
+			break
+		}
+		keyMarker, uploadIDMarker = result.NextKeyMarker, result.NextUploadIDMarker
+	}
+
+	return aborted, nil
+}
+
+func recordErr(mu *sync.Mutex, target *error, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if *target == nil {
+		*target = err
+	}
+}
+
+// etagMD5 strips the quotes OSS wraps ETags in, leaving the bare hex MD5.
+func etagMD5(etag string) string {
+	return strings.ToLower(strings.Trim(etag, `"`))
+}
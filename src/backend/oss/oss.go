@@ -0,0 +1,102 @@
+// Package oss implements the backend.Backend interface on top of Aliyun
+// Object Storage Service. It is the original, default provider.
+package oss
+
+import (
+	"fmt"
+	"strings"
+
+	aliyunoss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+
+	"github.com/yyobject/file-share/src/backend"
+)
+
+func init() {
+	backend.Register("oss", New)
+}
+
+// Options holds the Aliyun OSS connection settings, populated from the
+// OSS_* keys in the layered config sources.
+type Options struct {
+	AccessKeyID     string
+	AccessKeySecret string
+	BucketName      string
+	Endpoint        string
+	Domain          string
+}
+
+// Backend uploads to an Aliyun OSS bucket.
+type Backend struct {
+	opts   Options
+	bucket *aliyunoss.Bucket
+}
+
+// New builds an OSS backend.Backend from opts. Required keys:
+// access_key_id, access_key_secret, bucket_name, endpoint. domain is optional.
+func New(opts map[string]string) (backend.Backend, error) {
+	o := Options{
+		AccessKeyID:     opts["access_key_id"],
+		AccessKeySecret: opts["access_key_secret"],
+		BucketName:      opts["bucket_name"],
+		Endpoint:        opts["endpoint"],
+		Domain:          opts["domain"],
+	}
+
+	var missing []string
+	if o.AccessKeyID == "" {
+		missing = append(missing, "access_key_id")
+	}
+	if o.AccessKeySecret == "" {
+		missing = append(missing, "access_key_secret")
+	}
+	if o.BucketName == "" {
+		missing = append(missing, "bucket_name")
+	}
+	if o.Endpoint == "" {
+		missing = append(missing, "endpoint")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("oss: missing config: %s", strings.Join(missing, ", "))
+	}
+
+	client, err := aliyunoss.New(o.Endpoint, o.AccessKeyID, o.AccessKeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("oss: failed to create client: %v", err)
+	}
+
+	bucket, err := client.Bucket(o.BucketName)
+	if err != nil {
+		return nil, fmt.Errorf("oss: failed to get bucket: %v", err)
+	}
+
+	return &Backend{opts: o, bucket: bucket}, nil
+}
+
+// PutFile uploads localPath to key.
+func (b *Backend) PutFile(key, localPath string) error {
+	return b.bucket.PutObjectFromFile(key, localPath)
+}
+
+// PublicURL returns the URL a client can use to fetch key.
+func (b *Backend) PublicURL(key string) string {
+	if b.opts.Domain != "" {
+		domain := strings.TrimRight(b.opts.Domain, "/")
+		if !strings.HasPrefix(domain, "http://") && !strings.HasPrefix(domain, "https://") {
+			domain = "https://" + domain
+		}
+		return fmt.Sprintf("%s/%s", domain, key)
+	}
+
+	endpoint := b.opts.Endpoint
+	scheme := "https"
+	rest := endpoint
+
+	if strings.HasPrefix(endpoint, "http://") {
+		scheme = "http"
+		rest = strings.TrimPrefix(endpoint, "http://")
+	} else if strings.HasPrefix(endpoint, "https://") {
+		rest = strings.TrimPrefix(endpoint, "https://")
+	}
+
+	return fmt.Sprintf("%s://%s.%s/%s", scheme, b.opts.BucketName, rest, key)
+}
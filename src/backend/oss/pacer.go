@@ -0,0 +1,53 @@
+package oss
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	pacerMinSleep = 10 * time.Millisecond
+	pacerMaxSleep = 2 * time.Second
+	pacerMaxRetry = 5
+)
+
+// pacer retries an operation with exponential backoff, decaying back to
+// pacerMinSleep on success, the same shape as rclone's pacer.
+type pacer struct {
+	mu    sync.Mutex
+	sleep time.Duration
+}
+
+func newPacer() *pacer {
+	return &pacer{sleep: pacerMinSleep}
+}
+
+// call runs fn, retrying on error up to pacerMaxRetry times with
+// exponentially increasing sleeps capped at pacerMaxSleep.
+func (p *pacer) call(fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= pacerMaxRetry; attempt++ {
+		if err = fn(); err == nil {
+			p.mu.Lock()
+			p.sleep /= 2
+			if p.sleep < pacerMinSleep {
+				p.sleep = pacerMinSleep
+			}
+			p.mu.Unlock()
+			return nil
+		}
+
+		p.mu.Lock()
+		sleep := p.sleep
+		p.sleep *= 2
+		if p.sleep > pacerMaxSleep {
+			p.sleep = pacerMaxSleep
+		}
+		p.mu.Unlock()
+
+		if attempt < pacerMaxRetry {
+			time.Sleep(sleep)
+		}
+	}
+	return err
+}
@@ -0,0 +1,23 @@
+package oss
+
+import "strconv"
+
+// StatObject reports whether key already exists in the bucket and, if
+// so, its size, so --dedup can decide whether to skip re-uploading it.
+func (b *Backend) StatObject(key string) (bool, int64, error) {
+	exists, err := b.bucket.IsObjectExist(key)
+	if err != nil {
+		return false, 0, err
+	}
+	if !exists {
+		return false, 0, nil
+	}
+
+	header, err := b.bucket.GetObjectDetailedMeta(key)
+	if err != nil {
+		return false, 0, err
+	}
+
+	size, _ := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	return true, size, nil
+}
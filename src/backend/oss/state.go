@@ -0,0 +1,57 @@
+package oss
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// partState records one successfully uploaded part, enough to resume a
+// multipart upload without re-sending bytes OSS already has.
+type partState struct {
+	PartNumber int    `json:"part_number"`
+	ETag       string `json:"etag"`
+	MD5        string `json:"md5"`
+}
+
+// uploadState is the sidecar JSON persisted next to an in-progress
+// multipart upload so --resume can pick it back up after the process
+// is killed or a part fails after retries.
+type uploadState struct {
+	Key      string      `json:"key"`
+	UploadID string      `json:"upload_id"`
+	PartSize int64       `json:"part_size"`
+	Parts    []partState `json:"parts"`
+	Created  string      `json:"created"` // RFC3339, recorded for diagnostics only; GCUploads relies on the server-side Initiated time instead
+}
+
+// statePath returns the sidecar state file path for key, keyed by its
+// hash so arbitrary object keys (which may contain "/") are safe to use
+// as a filename.
+func statePath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(os.TempDir(), fmt.Sprintf("file-share-%s.state", hex.EncodeToString(sum[:])))
+}
+
+func loadState(path string) *uploadState {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var state uploadState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	return &state
+}
+
+func saveState(path string, state *uploadState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
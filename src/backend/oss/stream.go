@@ -0,0 +1,56 @@
+package oss
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	aliyunoss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// PutStream uploads the contents of r to key via a sequential multipart
+// upload, without requiring the data to already exist as a local file —
+// used by --stream to pipe a download straight into OSS. Unlike
+// PutFileMultipart it reads parts strictly in order and cannot resume,
+// since a live HTTP response body offers no random access to replay from.
+func (b *Backend) PutStream(key string, r io.Reader) error {
+	imur, err := b.bucket.InitiateMultipartUpload(key)
+	if err != nil {
+		return fmt.Errorf("oss: failed to initiate multipart upload: %v", err)
+	}
+
+	pc := newPacer()
+	buf := make([]byte, defaultPartSize)
+	var parts []aliyunoss.UploadPart
+
+	for partNumber := 1; ; partNumber++ {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 {
+			chunk := buf[:n]
+			var part aliyunoss.UploadPart
+			err := pc.call(func() error {
+				var uerr error
+				part, uerr = b.bucket.UploadPart(imur, bytes.NewReader(chunk), int64(n), partNumber)
+				return uerr
+			})
+			if err != nil {
+				b.bucket.AbortMultipartUpload(imur)
+				return fmt.Errorf("oss: part %d: %v", partNumber, err)
+			}
+			parts = append(parts, aliyunoss.UploadPart{PartNumber: partNumber, ETag: part.ETag})
+		}
+
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			b.bucket.AbortMultipartUpload(imur)
+			return fmt.Errorf("oss: read failed: %v", readErr)
+		}
+	}
+
+	if _, err := b.bucket.CompleteMultipartUpload(imur, parts); err != nil {
+		return fmt.Errorf("oss: failed to complete multipart upload: %v", err)
+	}
+	return nil
+}
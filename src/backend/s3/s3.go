@@ -0,0 +1,125 @@
+// Package s3 implements the backend.Backend interface on top of
+// S3-compatible object storage (AWS S3 and workalikes that accept a
+// custom endpoint, e.g. MinIO).
+package s3
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/yyobject/file-share/src/backend"
+)
+
+func init() {
+	backend.Register("s3", New)
+}
+
+// Options holds the S3 connection settings, populated from the S3_* keys
+// in the layered config sources.
+type Options struct {
+	AccessKeyID     string
+	AccessKeySecret string
+	BucketName      string
+	Region          string
+	Endpoint        string // optional, for S3-compatible services
+	Domain          string // optional custom public URL
+	ForcePathStyle  bool
+}
+
+// Backend uploads to an S3 (or S3-compatible) bucket.
+type Backend struct {
+	opts     Options
+	uploader *manager.Uploader
+}
+
+// New builds an S3 backend.Backend from opts. Required keys:
+// access_key_id, access_key_secret, bucket_name, region. endpoint, domain
+// and force_path_style are optional.
+func New(opts map[string]string) (backend.Backend, error) {
+	o := Options{
+		AccessKeyID:     opts["access_key_id"],
+		AccessKeySecret: opts["access_key_secret"],
+		BucketName:      opts["bucket_name"],
+		Region:          opts["region"],
+		Endpoint:        opts["endpoint"],
+		Domain:          opts["domain"],
+		ForcePathStyle:  opts["force_path_style"] == "true",
+	}
+
+	var missing []string
+	if o.AccessKeyID == "" {
+		missing = append(missing, "access_key_id")
+	}
+	if o.AccessKeySecret == "" {
+		missing = append(missing, "access_key_secret")
+	}
+	if o.BucketName == "" {
+		missing = append(missing, "bucket_name")
+	}
+	if o.Region == "" {
+		missing = append(missing, "region")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("s3: missing config: %s", strings.Join(missing, ", "))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(),
+		awsconfig.WithRegion(o.Region),
+		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(o.AccessKeyID, o.AccessKeySecret, "")),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("s3: failed to load config: %v", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(c *s3.Options) {
+		if o.Endpoint != "" {
+			c.BaseEndpoint = aws.String(o.Endpoint)
+		}
+		c.UsePathStyle = o.ForcePathStyle
+	})
+
+	return &Backend{opts: o, uploader: manager.NewUploader(client)}, nil
+}
+
+// PutFile uploads localPath to key.
+func (b *Backend) PutFile(key, localPath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = b.uploader.Upload(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String(b.opts.BucketName),
+		Key:    aws.String(key),
+		Body:   f,
+	})
+	return err
+}
+
+// PublicURL returns the URL a client can use to fetch key.
+func (b *Backend) PublicURL(key string) string {
+	if b.opts.Domain != "" {
+		domain := strings.TrimRight(b.opts.Domain, "/")
+		if !strings.HasPrefix(domain, "http://") && !strings.HasPrefix(domain, "https://") {
+			domain = "https://" + domain
+		}
+		return fmt.Sprintf("%s/%s", domain, key)
+	}
+	if b.opts.Endpoint != "" {
+		endpoint := strings.TrimRight(b.opts.Endpoint, "/")
+		if b.opts.ForcePathStyle {
+			return fmt.Sprintf("%s/%s/%s", endpoint, b.opts.BucketName, key)
+		}
+		return fmt.Sprintf("%s/%s", endpoint, key)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", b.opts.BucketName, b.opts.Region, key)
+}
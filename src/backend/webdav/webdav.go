@@ -0,0 +1,77 @@
+// Package webdav implements the backend.Backend interface on top of a
+// WebDAV server.
+package webdav
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/studio-b12/gowebdav"
+
+	"github.com/yyobject/file-share/src/backend"
+)
+
+func init() {
+	backend.Register("webdav", New)
+}
+
+// Options holds the WebDAV connection settings, populated from the
+// WEBDAV_* keys in the layered config sources.
+type Options struct {
+	URL      string
+	Username string
+	Password string
+	Domain   string // optional public URL prefix, if different from url
+}
+
+// Backend uploads to a WebDAV server.
+type Backend struct {
+	opts   Options
+	client *gowebdav.Client
+}
+
+// New builds a WebDAV backend.Backend from opts. Required key: url.
+// username and password are optional (for servers without auth). domain
+// is optional.
+func New(opts map[string]string) (backend.Backend, error) {
+	o := Options{
+		URL:      opts["url"],
+		Username: opts["username"],
+		Password: opts["password"],
+		Domain:   opts["domain"],
+	}
+
+	if o.URL == "" {
+		return nil, fmt.Errorf("webdav: missing config: url")
+	}
+
+	client := gowebdav.NewClient(o.URL, o.Username, o.Password)
+
+	return &Backend{opts: o, client: client}, nil
+}
+
+// PutFile uploads localPath to key.
+func (b *Backend) PutFile(key, localPath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	return b.client.WriteStream(key, f, info.Mode())
+}
+
+// PublicURL returns the URL a client can use to fetch key.
+func (b *Backend) PublicURL(key string) string {
+	base := b.opts.URL
+	if b.opts.Domain != "" {
+		base = b.opts.Domain
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(key, "/")
+}
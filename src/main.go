@@ -3,35 +3,52 @@ package main
 import (
 	"archive/zip"
 	"bufio"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"hash"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/term"
+
+	"github.com/yyobject/file-share/src/backend"
+	_ "github.com/yyobject/file-share/src/backend/azblob"
+	_ "github.com/yyobject/file-share/src/backend/b2"
+	_ "github.com/yyobject/file-share/src/backend/file"
+	_ "github.com/yyobject/file-share/src/backend/oss"
+	_ "github.com/yyobject/file-share/src/backend/s3"
+	_ "github.com/yyobject/file-share/src/backend/webdav"
+	"github.com/yyobject/file-share/src/crypt"
 )
 
-type Config struct {
-	AccessKeyID     string `json:"access_key_id"`
-	AccessKeySecret string `json:"access_key_secret"`
-	BucketName      string `json:"bucket_name"`
-	Endpoint        string `json:"endpoint"`
-	Domain          string `json:"domain,omitempty"`
-	Prefix          string `json:"prefix,omitempty"`
-}
+// defaultProvider is used when PROVIDER is not set, preserving the
+// tool's original Aliyun-OSS-only behavior.
+const defaultProvider = "oss"
 
 // Version number
 var Version = "1.2.0"
 
 type UploadResult struct {
-	File string `json:"file"`
-	URL  string `json:"url"`
+	File      string `json:"file"`
+	URL       string `json:"url"`
+	Deduped   bool   `json:"deduped,omitempty"`
+	Encrypted bool   `json:"encrypted,omitempty"`
 }
 
 type Result struct {
@@ -46,6 +63,7 @@ type Result struct {
 
 type CheckResult struct {
 	Ready        bool            `json:"ready"`
+	Provider     string          `json:"provider"`
 	EnvVars      map[string]bool `json:"env_vars"`
 	OptionalVars map[string]bool `json:"optional_vars"`
 	Missing      []string        `json:"missing"`
@@ -92,49 +110,17 @@ func loadEnvFile(path string) map[string]string {
 	return env
 }
 
-// Create config from .env file
-func configFromEnvFile(env map[string]string) *Config {
-	if env == nil {
-		return nil
-	}
-	return &Config{
-		AccessKeyID:     env["OSS_ACCESS_KEY_ID"],
-		AccessKeySecret: env["OSS_ACCESS_KEY_SECRET"],
-		BucketName:      env["OSS_BUCKET_NAME"],
-		Endpoint:        env["OSS_ENDPOINT"],
-		Domain:          env["OSS_DOMAIN"],
-		Prefix:          env["OSS_PREFIX"],
-	}
-}
-
-// Merge configs, high priority overrides low
-func mergeConfig(low, high *Config) *Config {
-	if low == nil {
-		return high
-	}
-	if high == nil {
-		return low
+// mergeEnv overlays high on top of low, high winning on key conflicts.
+// Either map may be nil.
+func mergeEnv(low, high map[string]string) map[string]string {
+	merged := make(map[string]string, len(low)+len(high))
+	for k, v := range low {
+		merged[k] = v
 	}
-	result := *low
-	if high.AccessKeyID != "" {
-		result.AccessKeyID = high.AccessKeyID
+	for k, v := range high {
+		merged[k] = v
 	}
-	if high.AccessKeySecret != "" {
-		result.AccessKeySecret = high.AccessKeySecret
-	}
-	if high.BucketName != "" {
-		result.BucketName = high.BucketName
-	}
-	if high.Endpoint != "" {
-		result.Endpoint = high.Endpoint
-	}
-	if high.Domain != "" {
-		result.Domain = high.Domain
-	}
-	if high.Prefix != "" {
-		result.Prefix = high.Prefix
-	}
-	return &result
+	return merged
 }
 
 // Get config sources description
@@ -170,106 +156,116 @@ func getConfigSources() []string {
 	return sources
 }
 
-func getConfig() (*Config, error) {
-	var config *Config
+// buildRawEnv layers the config sources (lowest to highest priority) into
+// a single flat map of raw KEY=VALUE pairs, same keys as found in the env
+// files and os.Environ(). It does not know about any particular backend's
+// option names; extractProviderOpts does that translation.
+func buildRawEnv() (map[string]string, error) {
+	var raw map[string]string
 
-	// If custom config file specified, use it
 	if customConfigPath != "" {
-		envConfig := configFromEnvFile(loadEnvFile(customConfigPath))
-		if envConfig == nil {
+		loaded := loadEnvFile(customConfigPath)
+		if loaded == nil {
 			return nil, fmt.Errorf("cannot read config file: %s", customConfigPath)
 		}
-		config = envConfig
+		raw = loaded
 	} else {
-		// Load configs from low to high priority
-
 		// 1. User home directory ~/.oss-upload.env (lowest priority)
 		if home, err := os.UserHomeDir(); err == nil {
-			envConfig := configFromEnvFile(loadEnvFile(filepath.Join(home, ".oss-upload.env")))
-			config = mergeConfig(config, envConfig)
+			raw = mergeEnv(raw, loadEnvFile(filepath.Join(home, ".oss-upload.env")))
 		}
 
 		// 2. Skill directory .env
 		if skillDir := getSkillDir(); skillDir != "" {
-			envConfig := configFromEnvFile(loadEnvFile(filepath.Join(skillDir, ".env")))
-			config = mergeConfig(config, envConfig)
+			raw = mergeEnv(raw, loadEnvFile(filepath.Join(skillDir, ".env")))
 		}
 
 		// 3. Current working directory .env
-		envConfig := configFromEnvFile(loadEnvFile(".env"))
-		config = mergeConfig(config, envConfig)
+		raw = mergeEnv(raw, loadEnvFile(".env"))
 	}
 
-	// 4. Environment variables (highest priority, always check)
-	envVarConfig := &Config{
-		AccessKeyID:     os.Getenv("OSS_ACCESS_KEY_ID"),
-		AccessKeySecret: os.Getenv("OSS_ACCESS_KEY_SECRET"),
-		BucketName:      os.Getenv("OSS_BUCKET_NAME"),
-		Endpoint:        os.Getenv("OSS_ENDPOINT"),
-		Domain:          os.Getenv("OSS_DOMAIN"),
-		Prefix:          os.Getenv("OSS_PREFIX"),
+	// 4. Environment variables (highest priority, always applied)
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 {
+			if raw == nil {
+				raw = map[string]string{}
+			}
+			raw[parts[0]] = parts[1]
+		}
 	}
-	config = mergeConfig(config, envVarConfig)
 
-	// Check required fields
-	if config == nil {
-		config = &Config{}
-	}
+	return raw, nil
+}
 
-	var missing []string
-	if config.AccessKeyID == "" {
-		missing = append(missing, "access_key_id")
-	}
-	if config.AccessKeySecret == "" {
-		missing = append(missing, "access_key_secret")
+// extractProviderOpts pulls out the <SCHEME>_* keys belonging to provider
+// (e.g. OSS_ACCESS_KEY_ID for provider "oss") and returns them with the
+// prefix stripped and the key lowercased, as the backend packages expect
+// (access_key_id). The generic PREFIX key is handled separately by
+// getConfig and is not part of a backend's own options.
+func extractProviderOpts(provider string, rawEnv map[string]string) map[string]string {
+	opts := map[string]string{}
+	schemePrefix := strings.ToUpper(provider) + "_"
+	for k, v := range rawEnv {
+		if !strings.HasPrefix(k, schemePrefix) {
+			continue
+		}
+		key := strings.ToLower(strings.TrimPrefix(k, schemePrefix))
+		if key == "prefix" {
+			continue
+		}
+		opts[key] = v
 	}
-	if config.BucketName == "" {
-		missing = append(missing, "bucket_name")
+	return opts
+}
+
+// getConfig resolves which storage provider to use and the options to
+// build it with. provider defaults to "oss" for backward compatibility
+// with installs that only ever set OSS_* variables.
+func getConfig() (provider string, prefix string, opts map[string]string, err error) {
+	rawEnv, err := buildRawEnv()
+	if err != nil {
+		return "", "", nil, err
 	}
-	if config.Endpoint == "" {
-		missing = append(missing, "endpoint")
+
+	provider = rawEnv["PROVIDER"]
+	if provider == "" {
+		provider = defaultProvider
 	}
 
-	if len(missing) > 0 {
-		return nil, fmt.Errorf("missing config: %s", strings.Join(missing, ", "))
+	prefix = rawEnv["PREFIX"]
+	if prefix == "" {
+		prefix = rawEnv[strings.ToUpper(provider)+"_PREFIX"]
 	}
 
-	return config, nil
+	opts = extractProviderOpts(provider, rawEnv)
+	return provider, prefix, opts, nil
 }
 
 func checkEnv() {
-	// Try to get config
-	config, _ := getConfig()
+	provider, prefix, opts, rawErr := getConfig()
 
 	result := CheckResult{
 		Ready:        true,
+		Provider:     provider,
 		EnvVars:      map[string]bool{},
 		OptionalVars: map[string]bool{},
 		Missing:      []string{},
 		Suggestions:  []string{},
 	}
 
-	// Check required config items
-	if config != nil {
-		result.EnvVars["access_key_id"] = config.AccessKeyID != ""
-		result.EnvVars["access_key_secret"] = config.AccessKeySecret != ""
-		result.EnvVars["bucket_name"] = config.BucketName != ""
-		result.EnvVars["endpoint"] = config.Endpoint != ""
-		result.OptionalVars["domain"] = config.Domain != ""
-		result.OptionalVars["prefix"] = config.Prefix != ""
+	if rawErr != nil {
+		result.Ready = false
+		result.Missing = append(result.Missing, rawErr.Error())
 	} else {
-		result.EnvVars["access_key_id"] = false
-		result.EnvVars["access_key_secret"] = false
-		result.EnvVars["bucket_name"] = false
-		result.EnvVars["endpoint"] = false
-		result.OptionalVars["domain"] = false
-		result.OptionalVars["prefix"] = false
-	}
+		for k, v := range opts {
+			result.EnvVars[k] = v != ""
+		}
+		result.OptionalVars["prefix"] = prefix != ""
 
-	for k, v := range result.EnvVars {
-		if !v {
+		if _, err := backend.New(provider, opts); err != nil {
 			result.Ready = false
-			result.Missing = append(result.Missing, k)
+			result.Missing = append(result.Missing, err.Error())
 		}
 	}
 
@@ -281,7 +277,7 @@ func checkEnv() {
 
 	if len(result.Missing) > 0 {
 		result.Suggestions = append(result.Suggestions,
-			fmt.Sprintf("Missing config: %s", strings.Join(result.Missing, ", ")))
+			fmt.Sprintf("Provider %q is not ready: %s", provider, strings.Join(result.Missing, "; ")))
 		result.Suggestions = append(result.Suggestions,
 			"Configuration methods (priority low to high):")
 		result.Suggestions = append(result.Suggestions,
@@ -291,7 +287,9 @@ func checkEnv() {
 		result.Suggestions = append(result.Suggestions,
 			"  3. .env (current directory)")
 		result.Suggestions = append(result.Suggestions,
-			"  4. Environment variables (OSS_ACCESS_KEY_ID, etc.)")
+			"  4. Environment variables (e.g. OSS_ACCESS_KEY_ID, S3_ACCESS_KEY_ID, ...)")
+		result.Suggestions = append(result.Suggestions,
+			fmt.Sprintf("  5. Select a provider with PROVIDER=<%s> (default %q)", strings.Join(backend.Schemes(), "|"), defaultProvider))
 	}
 
 	output, _ := json.MarshalIndent(result, "", "  ")
@@ -306,7 +304,309 @@ func checkEnv() {
 	}
 }
 
-func generateOSSKey(filename, prefix string, noTimestamp bool) string {
+// runGCUploads aborts multipart uploads the configured backend started
+// more than 24h ago and were never completed, e.g. because the process
+// was killed mid-upload.
+func runGCUploads() {
+	provider, _, opts, err := getConfig()
+	if err != nil {
+		outputError(err)
+	}
+
+	store, err := backend.New(provider, opts)
+	if err != nil {
+		outputError(fmt.Errorf("failed to initialize %s backend: %v", provider, err))
+	}
+
+	gcStore, ok := store.(backend.GCBackend)
+	if !ok {
+		outputError(fmt.Errorf("%s backend does not support --gc-uploads", provider))
+	}
+
+	aborted, err := gcStore.GCUploads(24 * time.Hour)
+	if err != nil {
+		outputError(err)
+	}
+
+	fmt.Printf("Aborted %d orphaned multipart upload(s)\n", aborted)
+}
+
+// resolvePassphrase resolves the --encrypt/--decrypt passphrase, in
+// priority order: OSS_ENCRYPT_PASSPHRASE, --passphrase-file, then an
+// interactive terminal prompt. Scripted use that supplies none of these
+// is rejected rather than silently encrypting with an empty passphrase.
+func resolvePassphrase(passphraseFile string) (string, error) {
+	if pp := os.Getenv("OSS_ENCRYPT_PASSPHRASE"); pp != "" {
+		return pp, nil
+	}
+
+	if passphraseFile != "" {
+		data, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read --passphrase-file: %v", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprint(os.Stderr, "Passphrase: ")
+		data, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %v", err)
+		}
+		return string(data), nil
+	}
+
+	return "", fmt.Errorf("no passphrase available: set OSS_ENCRYPT_PASSPHRASE, pass --passphrase-file, or run interactively")
+}
+
+// encryptToTemp encrypts localPath into a new temp file under a key
+// derived from passphrase, returning the temp file's path and the salt
+// used for the derivation. The salt (not the key) is stored in object
+// metadata so a later --decrypt can reproduce the same key.
+func encryptToTemp(localPath, passphrase string) (tmpPath string, salt []byte, err error) {
+	salt = make([]byte, crypt.SaltSize)
+	if _, err = rand.Read(salt); err != nil {
+		return "", nil, err
+	}
+
+	key, err := crypt.DeriveKey(passphrase, salt, crypt.DefaultParams)
+	if err != nil {
+		return "", nil, err
+	}
+
+	in, err := os.Open(localPath)
+	if err != nil {
+		return "", nil, err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp("", "oss-encrypt-*")
+	if err != nil {
+		return "", nil, err
+	}
+	tmpPath = out.Name()
+	defer out.Close()
+
+	if err = crypt.EncryptFile(out, in, key); err != nil {
+		os.Remove(tmpPath)
+		return "", nil, err
+	}
+
+	return tmpPath, salt, nil
+}
+
+// deriveNameKey derives the key --encrypt-names obfuscates filenames
+// under, from a fixed salt distinct from any file's content salt, so the
+// same filename always obfuscates the same way for a given passphrase.
+func deriveNameKey(passphrase string) ([]byte, error) {
+	return crypt.DeriveKey(passphrase, []byte("file-share-name-key-v1"), crypt.DefaultParams)
+}
+
+// encryptMeta returns the object metadata a --encrypt upload carries, so
+// --decrypt can re-derive the same key without any out-of-band state.
+func encryptMeta(salt []byte) map[string]string {
+	return map[string]string{
+		"algorithm": "AES-256-GCM",
+		"kdf":       "scrypt",
+		"salt":      base64.StdEncoding.EncodeToString(salt),
+		"kdf-n":     strconv.Itoa(crypt.DefaultParams.N),
+		"kdf-r":     strconv.Itoa(crypt.DefaultParams.R),
+		"kdf-p":     strconv.Itoa(crypt.DefaultParams.P),
+	}
+}
+
+// kdfParamsFromHeader reconstructs the scrypt cost parameters an
+// --encrypt upload stored in X-Oss-Meta-Kdf-N/R/P, so --decrypt derives
+// the key it was actually encrypted with instead of assuming the
+// current crypt.DefaultParams. Missing headers (objects uploaded before
+// these were recorded) fall back to crypt.DefaultParams.
+func kdfParamsFromHeader(h http.Header) (crypt.Params, error) {
+	params := crypt.DefaultParams
+
+	parse := func(name string, dst *int) error {
+		v := h.Get(name)
+		if v == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		*dst = n
+		return nil
+	}
+
+	if err := parse("X-Oss-Meta-Kdf-N", &params.N); err != nil {
+		return crypt.Params{}, err
+	}
+	if err := parse("X-Oss-Meta-Kdf-R", &params.R); err != nil {
+		return crypt.Params{}, err
+	}
+	if err := parse("X-Oss-Meta-Kdf-P", &params.P); err != nil {
+		return crypt.Params{}, err
+	}
+	return params, nil
+}
+
+// runDecrypt fetches the object at urlStr, decrypts it with the key
+// derived from the passphrase and the salt carried in its metadata
+// headers, and writes the plaintext to outputPath (stdout if empty).
+func runDecrypt(urlStr, passphraseFile, outputPath string) {
+	passphrase, err := resolvePassphrase(passphraseFile)
+	if err != nil {
+		outputError(err)
+	}
+
+	resp, err := http.Get(urlStr)
+	if err != nil {
+		outputError(fmt.Errorf("failed to fetch %s: %v", urlStr, err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		outputError(fmt.Errorf("failed to fetch %s: HTTP %d", urlStr, resp.StatusCode))
+	}
+
+	saltB64 := resp.Header.Get("X-Oss-Meta-Salt")
+	if saltB64 == "" {
+		outputError(fmt.Errorf("%s carries no --encrypt metadata (was it uploaded with --encrypt?)", urlStr))
+	}
+	salt, err := base64.StdEncoding.DecodeString(saltB64)
+	if err != nil {
+		outputError(fmt.Errorf("invalid salt metadata on %s: %v", urlStr, err))
+	}
+
+	if algorithm := resp.Header.Get("X-Oss-Meta-Algorithm"); algorithm != "" && algorithm != "AES-256-GCM" {
+		outputError(fmt.Errorf("%s was encrypted with unsupported algorithm %q", urlStr, algorithm))
+	}
+
+	params, err := kdfParamsFromHeader(resp.Header)
+	if err != nil {
+		outputError(fmt.Errorf("invalid KDF metadata on %s: %v", urlStr, err))
+	}
+
+	key, err := crypt.DeriveKey(passphrase, salt, params)
+	if err != nil {
+		outputError(err)
+	}
+
+	out := io.Writer(os.Stdout)
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			outputError(err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := crypt.DecryptFile(out, resp.Body, key); err != nil {
+		outputError(err)
+	}
+
+	if outputPath != "" && !quietMode {
+		fmt.Fprintf(os.Stderr, "Decrypted to %s\n", outputPath)
+	}
+}
+
+// uploadFile puts localPath under key, using the backend's multipart
+// upload path when the file is larger than thresholdMB and the backend
+// supports it, falling back to a plain PutFile otherwise.
+func uploadFile(store backend.Backend, key, localPath string, thresholdMB int64, parallel int, resume bool) error {
+	multipartStore, ok := store.(backend.MultipartBackend)
+	if !ok {
+		return store.PutFile(key, localPath)
+	}
+
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	if info.Size() <= thresholdMB*1024*1024 {
+		return store.PutFile(key, localPath)
+	}
+
+	return multipartStore.PutFileMultipart(key, localPath, backend.MultipartConfig{
+		Parallel: parallel,
+		Resume:   resume,
+	})
+}
+
+// hashFile returns the hex digest of localPath under the named algorithm
+// (sha1, sha256 or md5), used to derive a content-addressed key for
+// --dedup.
+func hashFile(localPath, algo string) (string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var h hash.Hash
+	switch algo {
+	case "sha1":
+		h = sha1.New()
+	case "sha256":
+		h = sha256.New()
+	case "md5":
+		h = md5.New()
+	default:
+		return "", fmt.Errorf("unsupported --dedup-hash %q (want sha1, sha256 or md5)", algo)
+	}
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// dedupKey derives the deterministic object key a file's content hashes
+// to, mirroring Mail.ru's "speedup" trick: identical bytes always map to
+// the same key, so a later upload of the same content can be skipped.
+func dedupKey(prefix, algo, digest, filename string) string {
+	key := fmt.Sprintf("by-hash/%s/%s/%s", algo, digest, filepath.Base(filename))
+	if prefix != "" {
+		key = strings.Trim(prefix, "/") + "/" + key
+	}
+	return key
+}
+
+// findDedupKey checks whether localPath's content-addressed object
+// already exists in store. It returns the dedup key to use whether or
+// not it was found (so the caller can upload under it on a miss), and
+// whether it was found with a matching size (a hit). The key is empty,
+// with no hit, when the file is smaller than minSize or store cannot
+// stat objects.
+func findDedupKey(store backend.Backend, localPath, prefix, hashAlgo string, minSize int64) (key string, hit bool, err error) {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return "", false, err
+	}
+	if info.Size() < minSize {
+		return "", false, nil
+	}
+
+	statStore, ok := store.(backend.StatBackend)
+	if !ok {
+		return "", false, nil
+	}
+
+	digest, err := hashFile(localPath, hashAlgo)
+	if err != nil {
+		return "", false, err
+	}
+	key = dedupKey(prefix, hashAlgo, digest, localPath)
+
+	exists, size, err := statStore.StatObject(key)
+	if err != nil {
+		return key, false, err
+	}
+	return key, exists && size == info.Size(), nil
+}
+
+func generateObjectKey(filename, prefix string, noTimestamp bool) string {
 	base := filepath.Base(filename)
 	var key string
 
@@ -325,31 +625,6 @@ func generateOSSKey(filename, prefix string, noTimestamp bool) string {
 	return key
 }
 
-func getFileURL(config *Config, ossKey string) string {
-	if config.Domain != "" {
-		domain := strings.TrimRight(config.Domain, "/")
-		// Add https:// if no protocol specified
-		if !strings.HasPrefix(domain, "http://") && !strings.HasPrefix(domain, "https://") {
-			domain = "https://" + domain
-		}
-		return fmt.Sprintf("%s/%s", domain, ossKey)
-	}
-
-	endpoint := config.Endpoint
-	scheme := "https"
-	rest := endpoint
-
-	if strings.HasPrefix(endpoint, "http://") {
-		scheme = "http"
-		rest = strings.TrimPrefix(endpoint, "http://")
-	} else if strings.HasPrefix(endpoint, "https://") {
-		scheme = "https"
-		rest = strings.TrimPrefix(endpoint, "https://")
-	}
-
-	return fmt.Sprintf("%s://%s.%s/%s", scheme, config.BucketName, rest, ossKey)
-}
-
 func createZip(files []string, zipName string, preservePath bool, baseDir string, filenameMap map[string]string) (string, error) {
 	tmpFile, err := os.CreateTemp("", "oss-upload-*.zip")
 	if err != nil {
@@ -511,9 +786,92 @@ func getFilenameFromURL(urlStr string) string {
 	return filename
 }
 
-// Download URL to temporary file
-func downloadURL(urlStr string) (string, string, error) {
-	resp, err := http.Get(urlStr)
+// buildHTTPClient returns the client shared by all downloads in this run,
+// enforcing timeout and a maximum redirect chain length.
+func buildHTTPClient(timeout time.Duration, maxRedirects int) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// httpGetWithRetry GETs urlStr, retrying with exponential backoff on
+// network errors and 5xx responses. 4xx responses are returned as-is;
+// retrying them would never succeed.
+func httpGetWithRetry(client *http.Client, urlStr string, maxRetries int) (*http.Response, error) {
+	sleep := 200 * time.Millisecond
+	var lastErr error
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		resp, err := client.Get(urlStr)
+		if err == nil && resp.StatusCode < 500 {
+			return resp, nil
+		}
+		if err == nil {
+			lastErr = fmt.Errorf("HTTP %d", resp.StatusCode)
+			resp.Body.Close()
+		} else {
+			lastErr = err
+		}
+
+		if attempt == maxRetries {
+			break
+		}
+		time.Sleep(sleep)
+		sleep *= 2
+	}
+
+	return nil, lastErr
+}
+
+// filenameFromContentDisposition extracts the filename from a
+// Content-Disposition header, preferring the RFC 5987 extended
+// filename*=UTF-8''... form over the plain filename= form when both are
+// present. mime.ParseMediaType already decodes the RFC 2231/5987
+// percent-encoding for us.
+func filenameFromContentDisposition(cd string) string {
+	_, params, err := mime.ParseMediaType(cd)
+	if err != nil {
+		return ""
+	}
+	if fn := params["filename*"]; fn != "" {
+		return fn
+	}
+	return params["filename"]
+}
+
+// strictLimitReader wraps r so that reading past limit bytes returns an
+// explicit "exceeds --max-download-size" error instead of the silent
+// truncation a bare io.LimitReader would produce, so an oversized body
+// fails the download/upload instead of completing as a corrupt object.
+func strictLimitReader(r io.Reader, limit int64) io.Reader {
+	return &strictLimitedReader{r: io.LimitReader(r, limit+1), limit: limit}
+}
+
+type strictLimitedReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (s *strictLimitedReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.read += int64(n)
+	if s.read > s.limit {
+		return n, fmt.Errorf("exceeds --max-download-size (%d bytes)", s.limit)
+	}
+	return n, err
+}
+
+// downloadURL fetches urlStr to a temp file, retrying on transient
+// errors and enforcing maxDownloadSize (0 = unlimited).
+func downloadURL(urlStr string, client *http.Client, maxRetries int, maxDownloadSize int64) (string, string, error) {
+	resp, err := httpGetWithRetry(client, urlStr, maxRetries)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to download %s: %v", urlStr, err)
 	}
@@ -525,15 +883,9 @@ func downloadURL(urlStr string) (string, string, error) {
 
 	// Get filename from URL or Content-Disposition header
 	filename := getFilenameFromURL(urlStr)
-
-	// Try to get filename from Content-Disposition header
 	if cd := resp.Header.Get("Content-Disposition"); cd != "" {
-		if idx := strings.Index(cd, "filename="); idx != -1 {
-			fn := cd[idx+9:]
-			fn = strings.Trim(fn, `"'`)
-			if fn != "" {
-				filename = fn
-			}
+		if fn := filenameFromContentDisposition(cd); fn != "" {
+			filename = fn
 		}
 	}
 
@@ -545,7 +897,12 @@ func downloadURL(urlStr string) (string, string, error) {
 	}
 	tmpPath := tmpFile.Name()
 
-	_, err = io.Copy(tmpFile, resp.Body)
+	var body io.Reader = resp.Body
+	if maxDownloadSize > 0 {
+		body = strictLimitReader(resp.Body, maxDownloadSize)
+	}
+
+	_, err = io.Copy(tmpFile, body)
 	tmpFile.Close()
 	if err != nil {
 		os.Remove(tmpPath)
@@ -555,6 +912,113 @@ func downloadURL(urlStr string) (string, string, error) {
 	return tmpPath, filename, nil
 }
 
+// downloadAll downloads urls concurrently, bounded by parallelDownloads,
+// and returns the results in the same order as urls.
+func downloadAll(urls []string, client *http.Client, maxRetries int, maxDownloadSize int64, parallelDownloads int, quiet bool) ([]DownloadedFile, error) {
+	results := make([]DownloadedFile, len(urls))
+
+	g := new(errgroup.Group)
+	g.SetLimit(parallelDownloads)
+
+	for i, u := range urls {
+		i, u := i, u
+		g.Go(func() error {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "Downloading: %s\n", u)
+			}
+			tmpPath, filename, err := downloadURL(u, client, maxRetries, maxDownloadSize)
+			if err != nil {
+				return err
+			}
+			results[i] = DownloadedFile{TmpPath: tmpPath, Filename: filename, OrigURL: u}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		for _, r := range results {
+			if r.TmpPath != "" {
+				os.Remove(r.TmpPath)
+			}
+		}
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// streamUpload downloads urlStr and pipes its body directly into store,
+// bypassing the local temp file entirely. store.PutStream already takes
+// an io.Reader, so unlike a Writer-based sink this needs no io.Pipe —
+// resp.Body (optionally wrapped in strictLimitReader) is handed over
+// as-is; since there's no local copy to inspect afterwards, an oversized
+// body must fail the upload outright rather than silently truncate.
+func streamUpload(urlStr string, client *http.Client, maxRetries int, maxDownloadSize int64, store backend.StreamBackend, ossPrefix string, noTimestamp bool) (UploadResult, error) {
+	resp, err := httpGetWithRetry(client, urlStr, maxRetries)
+	if err != nil {
+		return UploadResult{}, fmt.Errorf("failed to download %s: %v", urlStr, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return UploadResult{}, fmt.Errorf("failed to download %s: HTTP %d", urlStr, resp.StatusCode)
+	}
+
+	filename := getFilenameFromURL(urlStr)
+	if cd := resp.Header.Get("Content-Disposition"); cd != "" {
+		if fn := filenameFromContentDisposition(cd); fn != "" {
+			filename = fn
+		}
+	}
+
+	var body io.Reader = resp.Body
+	if maxDownloadSize > 0 {
+		body = strictLimitReader(resp.Body, maxDownloadSize)
+	}
+
+	ossKey := generateObjectKey(filename, ossPrefix, noTimestamp)
+	if err := store.PutStream(ossKey, body); err != nil {
+		return UploadResult{}, fmt.Errorf("failed to upload %s: %v", filename, err)
+	}
+
+	return UploadResult{File: filename, URL: store.PublicURL(ossKey)}, nil
+}
+
+// streamUploadAll streams urls into store concurrently, bounded by
+// parallelDownloads, and returns the results in the same order as urls.
+func streamUploadAll(urls []string, client *http.Client, maxRetries int, maxDownloadSize int64, parallelDownloads int, store backend.Backend, ossPrefix string, noTimestamp bool, quiet bool) ([]UploadResult, error) {
+	streamStore, ok := store.(backend.StreamBackend)
+	if !ok {
+		return nil, fmt.Errorf("--stream is not supported by this backend")
+	}
+
+	results := make([]UploadResult, len(urls))
+
+	g := new(errgroup.Group)
+	g.SetLimit(parallelDownloads)
+
+	for i, u := range urls {
+		i, u := i, u
+		g.Go(func() error {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "Streaming: %s\n", u)
+			}
+			result, err := streamUpload(u, client, maxRetries, maxDownloadSize, streamStore, ossPrefix, noTimestamp)
+			if err != nil {
+				return err
+			}
+			results[i] = result
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
 // DownloadedFile holds info about a downloaded URL
 type DownloadedFile struct {
 	TmpPath  string
@@ -595,10 +1059,263 @@ func outputError(err error) {
 	os.Exit(1)
 }
 
+// multiFlag collects repeated occurrences of a string flag, e.g.
+// --exclude "*.log" --exclude "tmp/*".
+type multiFlag []string
+
+func (m *multiFlag) String() string {
+	return strings.Join(*m, ",")
+}
+
+func (m *multiFlag) Set(v string) error {
+	*m = append(*m, v)
+	return nil
+}
+
+// SyncPlan is the diff between a local directory and its remote mirror,
+// also printed as-is by "sync --dry-run".
+type SyncPlan struct {
+	ToUpload  []string `json:"to_upload"`
+	ToUpdate  []string `json:"to_update"`
+	ToDelete  []string `json:"to_delete,omitempty"`
+	Unchanged []string `json:"unchanged"`
+}
+
+// SyncResult summarizes a non-dry-run "sync" invocation.
+type SyncResult struct {
+	Success   bool `json:"success"`
+	Uploaded  int  `json:"uploaded"`
+	Updated   int  `json:"updated"`
+	Deleted   int  `json:"deleted"`
+	Unchanged int  `json:"unchanged"`
+}
+
+// syncMatches reports whether rel should be synced: it must not match
+// any --exclude pattern, and if any --include patterns were given, it
+// must match one of those too.
+func syncMatches(rel string, excludes, includes multiFlag) bool {
+	for _, pattern := range excludes {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return false
+		}
+	}
+	if len(includes) == 0 {
+		return true
+	}
+	for _, pattern := range includes {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// fileChanged reports whether the local file at path differs from its
+// remote counterpart. With checksum, it compares MD5 against the
+// object's ETag (OSS ETags are the MD5 for non-multipart uploads); a
+// multipart ETag (the SDK's "<md5>-<n>" form, one hyphen after the hex
+// digest) isn't a plain MD5 of the whole file, so those objects fall
+// back to the size+mtime comparison instead of always reporting changed.
+// Without --checksum, every object is compared by size, then mtime.
+func fileChanged(path string, info os.FileInfo, remote backend.ListedObject, checksum bool) (bool, error) {
+	if checksum && !strings.Contains(remote.ETag, "-") {
+		digest, err := hashFile(path, "md5")
+		if err != nil {
+			return false, err
+		}
+		return !strings.EqualFold(digest, remote.ETag), nil
+	}
+	if info.Size() != remote.Size {
+		return true, nil
+	}
+	return info.ModTime().After(remote.LastModified), nil
+}
+
+// remoteKey joins prefix and rel into the object key a local file syncs to.
+func remoteKey(prefix, rel string) string {
+	if prefix == "" {
+		return rel
+	}
+	return strings.Trim(prefix, "/") + "/" + rel
+}
+
+// buildSyncPlan lists the remote objects under prefix, walks localDir,
+// and classifies every local file as new, changed or unchanged, and
+// every remaining remote object (not matched by a local file) as
+// to-delete. excludes/includes gate both sides of the diff, so a remote
+// object filtered out of the comparison is left alone rather than queued
+// for deletion just because no local file matched it.
+func buildSyncPlan(store backend.ListBackend, localDir, prefix string, checksum bool, excludes, includes multiFlag) (SyncPlan, error) {
+	remoteObjs, err := store.List(prefix)
+	if err != nil {
+		return SyncPlan{}, fmt.Errorf("failed to list remote objects: %v", err)
+	}
+
+	trimPrefix := ""
+	if prefix != "" {
+		trimPrefix = strings.Trim(prefix, "/") + "/"
+	}
+	remoteByRel := make(map[string]backend.ListedObject, len(remoteObjs))
+	for _, obj := range remoteObjs {
+		rel := strings.TrimPrefix(obj.Key, trimPrefix)
+		remoteByRel[rel] = obj
+	}
+
+	var plan SyncPlan
+	seen := map[string]bool{}
+
+	err = filepath.Walk(localDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if !syncMatches(rel, excludes, includes) {
+			return nil
+		}
+		seen[rel] = true
+
+		remote, exists := remoteByRel[rel]
+		if !exists {
+			plan.ToUpload = append(plan.ToUpload, rel)
+			return nil
+		}
+
+		changed, err := fileChanged(path, info, remote, checksum)
+		if err != nil {
+			return err
+		}
+		if changed {
+			plan.ToUpdate = append(plan.ToUpdate, rel)
+		} else {
+			plan.Unchanged = append(plan.Unchanged, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return SyncPlan{}, err
+	}
+
+	for rel, obj := range remoteByRel {
+		if !syncMatches(rel, excludes, includes) {
+			continue
+		}
+		if !seen[rel] {
+			plan.ToDelete = append(plan.ToDelete, obj.Key)
+		}
+	}
+
+	sort.Strings(plan.ToUpload)
+	sort.Strings(plan.ToUpdate)
+	sort.Strings(plan.ToDelete)
+	sort.Strings(plan.Unchanged)
+
+	return plan, nil
+}
+
+// runSync implements the "sync"/"mirror" subcommand: it reconciles a
+// local directory against a remote prefix, uploading files that are
+// missing or changed and, with --delete, removing remote objects that no
+// longer have a local counterpart.
+func runSync(args []string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	prefix := fs.String("prefix", "", "Remote prefix to sync under (overrides config)")
+	checksum := fs.Bool("checksum", false, "Compare MD5 instead of size+mtime")
+	deleteExtra := fs.Bool("delete", false, "Remove remote objects not present locally")
+	dryRun := fs.Bool("dry-run", false, "Print the sync plan as JSON and exit without changing anything")
+	configFile := fs.String("config", "", "Specify config file path")
+	var excludes, includes multiFlag
+	fs.Var(&excludes, "exclude", "Glob to exclude (relative path), repeatable")
+	fs.Var(&includes, "include", "Glob to include (relative path), repeatable; only matches sync if set")
+	fs.Parse(args)
+
+	rest := fs.Args()
+	if len(rest) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: file-share sync [options] <local-dir>")
+		os.Exit(1)
+	}
+	localDir := rest[0]
+
+	customConfigPath = *configFile
+	provider, configPrefix, opts, err := getConfig()
+	if err != nil {
+		outputError(err)
+	}
+
+	store, err := backend.New(provider, opts)
+	if err != nil {
+		outputError(fmt.Errorf("failed to initialize %s backend: %v", provider, err))
+	}
+	listStore, ok := store.(backend.ListBackend)
+	if !ok {
+		outputError(fmt.Errorf("%s backend does not support sync", provider))
+	}
+
+	remotePrefix := configPrefix
+	if *prefix != "" {
+		remotePrefix = *prefix
+	}
+
+	plan, err := buildSyncPlan(listStore, localDir, remotePrefix, *checksum, excludes, includes)
+	if err != nil {
+		outputError(err)
+	}
+
+	if *dryRun {
+		output, _ := json.MarshalIndent(plan, "", "  ")
+		fmt.Println(string(output))
+		return
+	}
+
+	for _, rel := range append(append([]string(nil), plan.ToUpload...), plan.ToUpdate...) {
+		if !quietMode {
+			fmt.Fprintf(os.Stderr, "Uploading: %s\n", rel)
+		}
+		if err := store.PutFile(remoteKey(remotePrefix, rel), filepath.Join(localDir, rel)); err != nil {
+			outputError(fmt.Errorf("failed to upload %s: %v", rel, err))
+		}
+	}
+
+	deleted := 0
+	if *deleteExtra {
+		for _, key := range plan.ToDelete {
+			if !quietMode {
+				fmt.Fprintf(os.Stderr, "Deleting: %s\n", key)
+			}
+			if err := listStore.DeleteObject(key); err != nil {
+				outputError(fmt.Errorf("failed to delete %s: %v", key, err))
+			}
+			deleted++
+		}
+	}
+
+	output, _ := json.MarshalIndent(SyncResult{
+		Success:   true,
+		Uploaded:  len(plan.ToUpload),
+		Updated:   len(plan.ToUpdate),
+		Deleted:   deleted,
+		Unchanged: len(plan.Unchanged),
+	}, "", "  ")
+	fmt.Println(string(output))
+}
+
 // Custom config file path
 var customConfigPath string
 
 func main() {
+	if len(os.Args) > 1 && (os.Args[1] == "sync" || os.Args[1] == "mirror") {
+		runSync(os.Args[2:])
+		return
+	}
+
 	zipMode := flag.Bool("zip", false, "Bundle files into zip before upload")
 	zipName := flag.String("zip-name", "", "Zip filename (auto-generated by default)")
 	prefix := flag.String("prefix", "", "OSS path prefix (overrides config)")
@@ -610,6 +1327,24 @@ func main() {
 	dryRun := flag.Bool("dry-run", false, "Preview mode, only show file list")
 	configFile := flag.String("config", "", "Specify config file path")
 	preservePath := flag.Bool("preserve-path", false, "Preserve directory structure when zipping")
+	parallel := flag.Int("parallel", 4, "Number of parts uploaded concurrently for multipart uploads")
+	resume := flag.Bool("resume", false, "Resume a previously interrupted multipart upload")
+	multipartThresholdMB := flag.Int64("multipart-threshold", 100, "Files larger than this many MiB are uploaded via multipart")
+	gcUploads := flag.Bool("gc-uploads", false, "Abort orphaned multipart uploads older than 24h and exit")
+	dedup := flag.Bool("dedup", false, "Skip uploading files whose content already exists remotely")
+	dedupHash := flag.String("dedup-hash", "sha1", "Hash algorithm for --dedup (sha1, sha256, md5)")
+	dedupMinSize := flag.Int64("dedup-min-size", 512, "Skip --dedup's hash round-trip for files smaller than this many bytes")
+	parallelDownloads := flag.Int("parallel-downloads", 4, "Number of URLs downloaded concurrently")
+	downloadTimeout := flag.Duration("download-timeout", 60*time.Second, "Per-download HTTP timeout")
+	downloadRetries := flag.Int("download-retries", 3, "Retries with exponential backoff on 5xx/network errors")
+	maxRedirects := flag.Int("max-redirects", 10, "Maximum HTTP redirects to follow per download")
+	maxDownloadSizeMB := flag.Int64("max-download-size", 0, "Abort a download after this many MiB (0 = unlimited)")
+	streamMode := flag.Bool("stream", false, "Pipe downloaded URLs directly into the upload, bypassing the temp file")
+	encryptMode := flag.Bool("encrypt", false, "Encrypt files client-side with AES-256-GCM before upload")
+	encryptNames := flag.Bool("encrypt-names", false, "Also obfuscate filenames when --encrypt is set")
+	passphraseFile := flag.String("passphrase-file", "", "Read the --encrypt/--decrypt passphrase from this file")
+	decryptURL := flag.String("decrypt", "", "Fetch an object by URL, decrypt it locally, and exit")
+	decryptOutput := flag.String("decrypt-output", "", "Write --decrypt output to this path instead of stdout")
 	flag.Parse()
 
 	// Version
@@ -628,6 +1363,18 @@ func main() {
 		return
 	}
 
+	// Garbage-collect orphaned multipart uploads
+	if *gcUploads {
+		runGCUploads()
+		return
+	}
+
+	// Fetch and decrypt a previously --encrypt'd object
+	if *decryptURL != "" {
+		runDecrypt(*decryptURL, *passphraseFile, *decryptOutput)
+		return
+	}
+
 	args := flag.Args()
 	if len(args) == 0 {
 		fmt.Fprintln(os.Stderr, "Usage: file-share [options] <files/URLs...>")
@@ -643,34 +1390,112 @@ func main() {
 		fmt.Fprintln(os.Stderr, "  --quiet           Quiet mode, only output URLs")
 		fmt.Fprintln(os.Stderr, "  --dry-run         Preview mode, only show file list")
 		fmt.Fprintln(os.Stderr, "  --config FILE     Specify config file path")
+		fmt.Fprintln(os.Stderr, "  --parallel N      Parts uploaded concurrently for multipart uploads (default 4)")
+		fmt.Fprintln(os.Stderr, "  --resume          Resume a previously interrupted multipart upload")
+		fmt.Fprintln(os.Stderr, "  --multipart-threshold MB  Multipart upload files larger than this (default 100)")
+		fmt.Fprintln(os.Stderr, "  --gc-uploads      Abort orphaned multipart uploads older than 24h and exit")
+		fmt.Fprintln(os.Stderr, "  --dedup           Skip uploading files whose content already exists remotely")
+		fmt.Fprintln(os.Stderr, "  --dedup-hash ALGO Hash algorithm for --dedup (default sha1)")
+		fmt.Fprintln(os.Stderr, "  --dedup-min-size BYTES  Skip --dedup below this size (default 512)")
+		fmt.Fprintln(os.Stderr, "  --parallel-downloads N  URLs downloaded concurrently (default 4)")
+		fmt.Fprintln(os.Stderr, "  --download-timeout DUR  Per-download HTTP timeout (default 60s)")
+		fmt.Fprintln(os.Stderr, "  --download-retries N    Retries on 5xx/network errors (default 3)")
+		fmt.Fprintln(os.Stderr, "  --max-redirects N       Max redirects per download (default 10)")
+		fmt.Fprintln(os.Stderr, "  --max-download-size MB  Abort a download past this size (default unlimited)")
+		fmt.Fprintln(os.Stderr, "  --stream          Pipe downloaded URLs directly into the upload")
+		fmt.Fprintln(os.Stderr, "  --encrypt         Encrypt files client-side with AES-256-GCM before upload")
+		fmt.Fprintln(os.Stderr, "  --encrypt-names   Also obfuscate filenames (with --encrypt)")
+		fmt.Fprintln(os.Stderr, "  --passphrase-file FILE  Read the --encrypt/--decrypt passphrase from FILE")
+		fmt.Fprintln(os.Stderr, "  --decrypt URL     Fetch an object by URL, decrypt it locally, and exit")
+		fmt.Fprintln(os.Stderr, "  --decrypt-output FILE   Write --decrypt output to FILE instead of stdout")
 		fmt.Fprintln(os.Stderr, "  --version         Show version")
 		fmt.Fprintln(os.Stderr, "")
 		fmt.Fprintln(os.Stderr, "URL Support:")
 		fmt.Fprintln(os.Stderr, "  Pass URLs (http:// or https://) to download and re-upload to OSS")
 		fmt.Fprintln(os.Stderr, "  Example: oss-upload https://example.com/image.png")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "Subcommands:")
+		fmt.Fprintln(os.Stderr, "  sync [options] <local-dir>   Mirror a local directory to a remote prefix")
+		fmt.Fprintln(os.Stderr, "                                (aka mirror; run with --dry-run to preview)")
 		os.Exit(1)
 	}
 
+	if *streamMode && *zipMode {
+		outputError(fmt.Errorf("--stream cannot be combined with --zip"))
+	}
+	if *encryptMode && *zipMode {
+		outputError(fmt.Errorf("--encrypt cannot be combined with --zip"))
+	}
+
+	httpClient := buildHTTPClient(*downloadTimeout, *maxRedirects)
+	maxDownloadSize := *maxDownloadSizeMB * 1024 * 1024
+
 	// Separate URLs from local files
 	var localArgs []string
-	var downloadedFiles []DownloadedFile
+	var urlArgs []string
 
 	for _, arg := range args {
 		if isURL(arg) {
-			if !quietMode {
-				fmt.Fprintf(os.Stderr, "Downloading: %s\n", arg)
+			urlArgs = append(urlArgs, arg)
+		} else {
+			localArgs = append(localArgs, arg)
+		}
+	}
+
+	// --stream has no local staging step to preview against, so --dry-run
+	// must short-circuit here, before the real streamUploadAll call below
+	// ever touches the bucket.
+	if *dryRun && *streamMode {
+		if len(urlArgs) > 0 {
+			fmt.Printf("Will stream %d URL(s) directly into the upload:\n", len(urlArgs))
+			for _, u := range urlArgs {
+				fmt.Printf("  %s (from URL)\n", getFilenameFromURL(u))
 			}
-			tmpPath, filename, err := downloadURL(arg)
+		}
+		if len(localArgs) > 0 {
+			files, err := expandFiles(localArgs, *recursive)
 			if err != nil {
 				outputError(err)
 			}
-			downloadedFiles = append(downloadedFiles, DownloadedFile{
-				TmpPath:  tmpPath,
-				Filename: filename,
-				OrigURL:  arg,
-			})
-		} else {
-			localArgs = append(localArgs, arg)
+			fmt.Printf("Will upload %d file(s):\n", len(files))
+			for _, f := range files {
+				fmt.Printf("  %s\n", f)
+			}
+		}
+		os.Exit(0)
+	}
+
+	// In --stream mode, URLs are piped straight into the upload further
+	// down and never touch the files/downloadedFiles pipeline below.
+	var streamResults []UploadResult
+	var downloadedFiles []DownloadedFile
+
+	if *streamMode {
+		if len(urlArgs) > 0 {
+			provider, configPrefix, opts, err := getConfig()
+			if err != nil {
+				outputError(err)
+			}
+			store, err := backend.New(provider, opts)
+			if err != nil {
+				outputError(fmt.Errorf("failed to initialize %s backend: %v", provider, err))
+			}
+
+			ossPrefix := configPrefix
+			if *prefix != "" {
+				ossPrefix = *prefix
+			}
+
+			streamResults, err = streamUploadAll(urlArgs, httpClient, *downloadRetries, maxDownloadSize, *parallelDownloads, store, ossPrefix, *noTimestamp, quietMode)
+			if err != nil {
+				outputError(err)
+			}
+		}
+	} else if len(urlArgs) > 0 {
+		var err error
+		downloadedFiles, err = downloadAll(urlArgs, httpClient, *downloadRetries, maxDownloadSize, *parallelDownloads, quietMode)
+		if err != nil {
+			outputError(err)
 		}
 	}
 
@@ -699,6 +1524,10 @@ func main() {
 	}
 
 	if len(files) == 0 {
+		if len(streamResults) > 0 {
+			outputResult(Result{Success: true, Mode: "separate", Results: streamResults})
+			return
+		}
 		outputError(fmt.Errorf("no files to upload"))
 	}
 
@@ -724,24 +1553,18 @@ func main() {
 	}
 
 	// Get config
-	config, err := getConfig()
+	storeProvider, configPrefix, opts, err := getConfig()
 	if err != nil {
 		outputError(err)
 	}
 
-	// Create OSS client
-	client, err := oss.New(config.Endpoint, config.AccessKeyID, config.AccessKeySecret)
+	store, err := backend.New(storeProvider, opts)
 	if err != nil {
-		outputError(fmt.Errorf("failed to create OSS client: %v", err))
-	}
-
-	bucket, err := client.Bucket(config.BucketName)
-	if err != nil {
-		outputError(fmt.Errorf("failed to get bucket: %v", err))
+		outputError(fmt.Errorf("failed to initialize %s backend: %v", storeProvider, err))
 	}
 
 	// Determine prefix
-	ossPrefix := config.Prefix
+	ossPrefix := configPrefix
 	if *prefix != "" {
 		ossPrefix = *prefix
 	}
@@ -774,13 +1597,13 @@ func main() {
 		defer os.Remove(zipPath)
 
 		// Auto-generated name already has timestamp, skip adding another
-		ossKey := generateOSSKey(name, ossPrefix, *noTimestamp || autoGenerated)
-		err = bucket.PutObjectFromFile(ossKey, zipPath)
+		ossKey := generateObjectKey(name, ossPrefix, *noTimestamp || autoGenerated)
+		err = uploadFile(store, ossKey, zipPath, *multipartThresholdMB, *parallel, *resume)
 		if err != nil {
 			outputError(fmt.Errorf("upload failed: %v", err))
 		}
 
-		url := getFileURL(config, ossKey)
+		url := store.PublicURL(ossKey)
 		filesIncluded := make([]string, len(files))
 		for i, f := range files {
 			// Use original filename for downloaded files
@@ -807,7 +1630,23 @@ func main() {
 		})
 	} else {
 		// Separate upload mode
-		var results []UploadResult
+		results := append([]UploadResult(nil), streamResults...)
+
+		var passphrase string
+		var nameKey []byte
+		if *encryptMode {
+			passphrase, err = resolvePassphrase(*passphraseFile)
+			if err != nil {
+				outputError(err)
+			}
+			if *encryptNames {
+				nameKey, err = deriveNameKey(passphrase)
+				if err != nil {
+					outputError(fmt.Errorf("failed to derive name key: %v", err))
+				}
+			}
+		}
+
 		for _, file := range files {
 			// Use original filename for downloaded files
 			displayName := filepath.Base(file)
@@ -817,16 +1656,64 @@ func main() {
 				keyName = origName
 			}
 
-			ossKey := generateOSSKey(keyName, ossPrefix, *noTimestamp)
-			err = bucket.PutObjectFromFile(ossKey, file)
-			if err != nil {
-				outputError(fmt.Errorf("failed to upload %s: %v", displayName, err))
+			uploadPath := file
+			encrypted := false
+			var encSalt []byte
+
+			if *encryptMode {
+				var tmpPath string
+				var eerr error
+				tmpPath, encSalt, eerr = encryptToTemp(file, passphrase)
+				if eerr != nil {
+					outputError(fmt.Errorf("failed to encrypt %s: %v", displayName, eerr))
+				}
+				defer os.Remove(tmpPath)
+				uploadPath = tmpPath
+				encrypted = true
+
+				if *encryptNames {
+					ext := filepath.Ext(keyName)
+					keyName = crypt.ObfuscateName(strings.TrimSuffix(filepath.Base(keyName), ext), nameKey) + ext
+				}
+			}
+
+			ossKey := generateObjectKey(keyName, ossPrefix, *noTimestamp)
+			deduped := false
+
+			// A random nonce makes every --encrypt upload's ciphertext
+			// unique, so content-addressed dedup would never hit anyway.
+			if *dedup && !*encryptMode {
+				dedupedKey, hit, derr := findDedupKey(store, file, ossPrefix, *dedupHash, *dedupMinSize)
+				if derr != nil {
+					outputError(fmt.Errorf("dedup check failed for %s: %v", displayName, derr))
+				}
+				if dedupedKey != "" {
+					ossKey = dedupedKey
+					deduped = hit
+				}
+			}
+
+			if encrypted {
+				metaStore, ok := store.(backend.MetadataBackend)
+				if !ok {
+					outputError(fmt.Errorf("%s backend does not support --encrypt", storeProvider))
+				}
+				if err = metaStore.PutFileWithMeta(ossKey, uploadPath, encryptMeta(encSalt)); err != nil {
+					outputError(fmt.Errorf("failed to upload %s: %v", displayName, err))
+				}
+			} else if !deduped {
+				err = uploadFile(store, ossKey, uploadPath, *multipartThresholdMB, *parallel, *resume)
+				if err != nil {
+					outputError(fmt.Errorf("failed to upload %s: %v", displayName, err))
+				}
 			}
 
-			url := getFileURL(config, ossKey)
+			url := store.PublicURL(ossKey)
 			results = append(results, UploadResult{
-				File: displayName,
-				URL:  url,
+				File:      displayName,
+				URL:       url,
+				Deduped:   deduped,
+				Encrypted: encrypted,
 			})
 		}
 